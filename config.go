@@ -12,6 +12,10 @@ package service
 //			1. Merging configs is usually not trivial, since there are no optional fields (only zero-values) in go
 //			2. A base Config struct method will not have access to any useful fields when implemented on a real config
 //				object.
+//		To help with (1), wrap fields whose zero-value is meaningful in Optional[T] and merge via MergeStructs, which
+//		treats an unset Optional as "inherit the base value" instead of clobbering it. LoadConfig reduces multiple
+//		ConfigSources (files, readers, in-memory configs) into a single ConfigI using the same MergeWith a service
+//		would call directly.
 
 type ConfigI interface {
 	MergeWith(config ConfigI) ConfigI
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// ConfigSource is one input to LoadConfig: a JSON file path, a reader of JSON,
+// or an already-constructed ConfigI to merge in directly.
+type ConfigSource interface {
+	loadInto(target ConfigI) (ConfigI, error)
+}
+
+// FileConfigSource reads and decodes a JSON config file.
+type FileConfigSource string
+
+func (src FileConfigSource) loadInto(target ConfigI) (ConfigI, error) {
+	file, err := os.Open(string(src))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return decodeConfig(target, file)
+}
+
+// ReaderConfigSource decodes JSON config from an arbitrary io.Reader.
+type ReaderConfigSource struct {
+	Reader io.Reader
+}
+
+func (src ReaderConfigSource) loadInto(target ConfigI) (ConfigI, error) {
+	return decodeConfig(target, src.Reader)
+}
+
+// InMemoryConfigSource merges in a ConfigI that's already been constructed,
+// e.g. one built up from flags or another service's config.
+type InMemoryConfigSource struct {
+	Config ConfigI
+}
+
+func (src InMemoryConfigSource) loadInto(_ ConfigI) (ConfigI, error) {
+	return src.Config, nil
+}
+
+func decodeConfig(target ConfigI, r io.Reader) (ConfigI, error) {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("service: config target must be a non-nil pointer, got %T", target)
+	}
+	next := reflect.New(targetType.Elem())
+	if err := json.NewDecoder(r).Decode(next.Interface()); err != nil {
+		return nil, err
+	}
+	decoded, ok := next.Interface().(ConfigI)
+	if !ok {
+		return nil, fmt.Errorf("service: %s does not implement ConfigI", targetType.Elem())
+	}
+	return decoded, nil
+}
+
+// LoadConfig reduces sources left-to-right on top of target, calling
+// MergeWith on each in turn so later sources override earlier ones,
+// mirroring Docker Compose's multi `--compose-file` override semantics.
+// target itself is the base of the reduction and is never mutated.
+func LoadConfig(target ConfigI, sources ...ConfigSource) (ConfigI, error) {
+	merged := target
+	for _, src := range sources {
+		next, err := src.loadInto(target)
+		if err != nil {
+			return nil, err
+		}
+		merged = next.MergeWith(merged)
+	}
+	return merged, nil
+}
+
+// Optional distinguishes a field explicitly set to its zero value from a
+// field left unset. MergeStructs treats Set == false as "inherit the base's
+// value" rather than clobbering it with override's zero value.
+type Optional[T any] struct {
+	Set   bool
+	Value T
+}
+
+// Some builds a set Optional, e.g. for an override source field whose zero
+// value is meaningful (ConfigFieldOne: service.Some(0)).
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Set: true, Value: v}
+}
+
+func isOptionalType(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	setField, ok := t.FieldByName("Set")
+	if !ok || setField.Type.Kind() != reflect.Bool {
+		return false
+	}
+	_, ok = t.FieldByName("Value")
+	return ok
+}
+
+// FieldMerger merges an override field value on top of a base field value of
+// the same type, returning the merged value.
+type FieldMerger func(base, override reflect.Value) reflect.Value
+
+var fieldMergersByType = make(map[reflect.Type]FieldMerger)
+
+// RegisterFieldMerger installs a FieldMerger that MergeStructs consults for
+// every field of type t, taking precedence over the default merge rules
+// (mirrors mergo's per-type "specials").
+func RegisterFieldMerger(t reflect.Type, merger FieldMerger) {
+	fieldMergersByType[t] = merger
+}
+
+// MergeStructs walks base and override, which must be structs (or pointers
+// to structs) of the same type, and returns a *T with override's set fields
+// layered on top of base. It's meant to be called from a ConfigI's
+// MergeWith so implementers don't have to hand-write field-by-field merge
+// logic. Fields merge as follows:
+//   - Optional[T] fields: override wins only if Set is true.
+//   - Slices: appended to base's, unless tagged `merge:"override"`, in which
+//     case a non-empty override slice replaces base's outright.
+//   - Maps: deep-merged key by key; a colliding map or struct value is
+//     merged recursively, anything else has override win outright.
+//   - Nested structs: merged recursively.
+//   - Everything else: override wins unless it's the zero value.
+//
+// A type registered via RegisterFieldMerger takes precedence over all of the
+// above.
+func MergeStructs(base, override any) any {
+	baseVal := indirectStruct(reflect.ValueOf(base))
+	overrideVal := indirectStruct(reflect.ValueOf(override))
+	if baseVal.Type() != overrideVal.Type() {
+		panic(fmt.Sprintf("service: MergeStructs requires base and override to share a type, got %s and %s", baseVal.Type(), overrideVal.Type()))
+	}
+
+	structType := baseVal.Type()
+	merged := reflect.New(structType).Elem()
+	for i := 0; i < structType.NumField(); i++ {
+		if !merged.Field(i).CanSet() {
+			continue
+		}
+		mergedField := mergeFieldValue(structType.Field(i), baseVal.Field(i), overrideVal.Field(i))
+		merged.Field(i).Set(mergedField)
+	}
+
+	result := reflect.New(structType)
+	result.Elem().Set(merged)
+	return result.Interface()
+}
+
+func mergeFieldValue(field reflect.StructField, base, override reflect.Value) reflect.Value {
+	if merger, ok := fieldMergersByType[override.Type()]; ok {
+		return merger(base, override)
+	}
+
+	if isOptionalType(override.Type()) {
+		if !override.FieldByName("Set").Bool() {
+			return base
+		}
+		return override
+	}
+
+	switch override.Kind() {
+	case reflect.Slice:
+		if override.Len() == 0 {
+			return base
+		}
+		if field.Tag.Get("merge") == "override" {
+			return override
+		}
+		return reflect.AppendSlice(base, override)
+	case reflect.Map:
+		if base.IsNil() && override.IsNil() {
+			return override
+		}
+		merged := reflect.MakeMap(override.Type())
+		for _, k := range base.MapKeys() {
+			merged.SetMapIndex(k, base.MapIndex(k))
+		}
+		for _, k := range override.MapKeys() {
+			overrideVal := override.MapIndex(k)
+			if baseVal := base.MapIndex(k); baseVal.IsValid() && isDeepMergeKind(baseVal.Kind()) && overrideVal.Kind() == baseVal.Kind() {
+				overrideVal = mergeFieldValue(field, baseVal, overrideVal)
+			}
+			merged.SetMapIndex(k, overrideVal)
+		}
+		return merged
+	case reflect.Struct:
+		mergedStruct := MergeStructs(base.Interface(), override.Interface())
+		return reflect.ValueOf(mergedStruct).Elem()
+	default:
+		if override.IsZero() {
+			return base
+		}
+		return override
+	}
+}
+
+// isDeepMergeKind reports whether mergeFieldValue knows how to merge two
+// colliding map values of this kind, rather than letting override win
+// outright.
+func isDeepMergeKind(k reflect.Kind) bool {
+	return k == reflect.Map || k == reflect.Struct
+}
+
+func indirectStruct(v reflect.Value) reflect.Value {
+	if v.Kind() == reflect.Ptr {
+		return v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type mergeTestConfig struct {
+	Name     Optional[string]
+	Count    Optional[int]
+	Tags     []string
+	Replaced []string `merge:"override"`
+	Labels   map[string]string
+	Nested   mergeTestNested
+}
+
+type mergeTestNested struct {
+	A int
+	B int
+}
+
+func (c *mergeTestConfig) MergeWith(base ConfigI) ConfigI {
+	return MergeStructs(base, c).(*mergeTestConfig)
+}
+
+var _ = Describe("MergeStructs", func() {
+	It("lets an unset Optional field inherit from base", func() {
+		base := &mergeTestConfig{Name: Some("base"), Count: Some(1)}
+		override := &mergeTestConfig{}
+
+		merged := override.MergeWith(base).(*mergeTestConfig)
+
+		Expect(merged.Name.Value).To(Equal("base"))
+		Expect(merged.Count.Value).To(Equal(1))
+	})
+
+	It("lets a set Optional field override base, even to its zero value", func() {
+		base := &mergeTestConfig{Count: Some(5)}
+		override := &mergeTestConfig{Count: Some(0)}
+
+		merged := override.MergeWith(base).(*mergeTestConfig)
+
+		Expect(merged.Count.Set).To(BeTrue())
+		Expect(merged.Count.Value).To(Equal(0))
+	})
+
+	It("appends slices by default", func() {
+		base := &mergeTestConfig{Tags: []string{"a", "b"}}
+		override := &mergeTestConfig{Tags: []string{"c"}}
+
+		merged := override.MergeWith(base).(*mergeTestConfig)
+
+		Expect(merged.Tags).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	It("replaces a slice outright when tagged merge:\"override\"", func() {
+		base := &mergeTestConfig{Replaced: []string{"a", "b"}}
+		override := &mergeTestConfig{Replaced: []string{"c"}}
+
+		merged := override.MergeWith(base).(*mergeTestConfig)
+
+		Expect(merged.Replaced).To(Equal([]string{"c"}))
+	})
+
+	It("deep-merges maps key by key", func() {
+		base := &mergeTestConfig{Labels: map[string]string{"x": "base-x", "y": "base-y"}}
+		override := &mergeTestConfig{Labels: map[string]string{"x": "override-x"}}
+
+		merged := override.MergeWith(base).(*mergeTestConfig)
+
+		Expect(merged.Labels).To(Equal(map[string]string{"x": "override-x", "y": "base-y"}))
+	})
+
+	It("recursively merges colliding map values that are themselves structs", func() {
+		type inner struct {
+			A int
+			B int
+		}
+		type outer struct {
+			M map[string]inner
+		}
+		base := outer{M: map[string]inner{"k": {A: 1, B: 2}}}
+		override := outer{M: map[string]inner{"k": {A: 0, B: 5}}}
+
+		merged := MergeStructs(base, override).(*outer)
+
+		Expect(merged.M["k"]).To(Equal(inner{A: 1, B: 5}))
+	})
+
+	It("merges nested structs recursively", func() {
+		base := &mergeTestConfig{Nested: mergeTestNested{A: 1, B: 2}}
+		override := &mergeTestConfig{Nested: mergeTestNested{B: 5}}
+
+		merged := override.MergeWith(base).(*mergeTestConfig)
+
+		Expect(merged.Nested).To(Equal(mergeTestNested{A: 1, B: 5}))
+	})
+
+	It("panics when base and override are different types", func() {
+		Expect(func() {
+			MergeStructs(&mergeTestConfig{}, &mergeTestNested{})
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("LoadConfig", func() {
+	It("reduces sources left-to-right, later sources overriding earlier ones", func() {
+		base := &mergeTestConfig{Tags: []string{"base"}}
+		sources := []ConfigSource{
+			InMemoryConfigSource{Config: &mergeTestConfig{Tags: []string{"one"}}},
+			InMemoryConfigSource{Config: &mergeTestConfig{Tags: []string{"two"}}},
+		}
+
+		merged, err := LoadConfig(base, sources...)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.(*mergeTestConfig).Tags).To(Equal([]string{"base", "one", "two"}))
+	})
+
+	It("decodes a ReaderConfigSource as JSON and merges it in", func() {
+		base := &mergeTestConfig{Name: Some("base")}
+		reader := strings.NewReader(`{"Count": {"Set": true, "Value": 3}}`)
+
+		merged, err := LoadConfig(base, ReaderConfigSource{Reader: reader})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(merged.(*mergeTestConfig).Count.Value).To(Equal(3))
+	})
+
+	It("surfaces a decode error from a ReaderConfigSource", func() {
+		base := &mergeTestConfig{}
+		reader := strings.NewReader(`not json`)
+
+		_, err := LoadConfig(base, ReaderConfigSource{Reader: reader})
+
+		Expect(err).To(HaveOccurred())
+	})
+})
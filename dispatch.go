@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+)
+
+// DispatchMode selects whether Dispatch runs handlers synchronously on the
+// caller's goroutine (the default) or hands the event off to a worker pool.
+type DispatchMode int
+
+const (
+	DispatchSync DispatchMode = iota
+	DispatchAsync
+)
+
+// OverflowPolicy decides what Dispatch does in DispatchAsync mode when a
+// service's dispatch queue is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest evicts the queue's oldest unprocessed event to make room.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the event that just failed to enqueue.
+	DropNewest
+	// Block waits until the queue has room.
+	Block
+	// Error returns ErrDispatchQueueFull instead of enqueueing.
+	Error
+)
+
+// defaultDispatchQueueSize is used when SetDispatchQueueSize hasn't been
+// called before async dispatch starts.
+const defaultDispatchQueueSize = 64
+
+// ErrDispatchQueueFull is returned by Dispatch when the OverflowPolicy is
+// Error and the service's dispatch queue has no room for the event.
+var ErrDispatchQueueFull = errors.New("service: dispatch queue is full")
+
+// SetDispatchMode selects sync or async dispatch. Must be called before the
+// first async Dispatch to take effect on the worker pool's shape; see
+// SetDispatchWorkers and SetDispatchQueueSize.
+func (s *Service) SetDispatchMode(mode DispatchMode) {
+	s.dispatchConfigMu.Lock()
+	defer s.dispatchConfigMu.Unlock()
+	s.dispatchMode = mode
+}
+
+// SetDispatchWorkers sets how many worker goroutines drain the dispatch
+// queue in async mode. Only takes effect if called before the first async
+// Dispatch call.
+func (s *Service) SetDispatchWorkers(n int) {
+	s.dispatchConfigMu.Lock()
+	defer s.dispatchConfigMu.Unlock()
+	s.dispatchWorkers = n
+}
+
+// SetDispatchQueueSize sets the bound on each worker's event queue in async
+// mode. Only takes effect if called before the first async Dispatch call.
+func (s *Service) SetDispatchQueueSize(n int) {
+	s.dispatchConfigMu.Lock()
+	defer s.dispatchConfigMu.Unlock()
+	s.dispatchQueueSize = n
+}
+
+// SetOverflowPolicy sets how async Dispatch behaves when the dispatch queue
+// is full.
+func (s *Service) SetOverflowPolicy(policy OverflowPolicy) {
+	s.dispatchConfigMu.Lock()
+	defer s.dispatchConfigMu.Unlock()
+	s.overflowPolicy = policy
+}
+
+func (s *Service) dispatchModeAndPolicy() (DispatchMode, OverflowPolicy) {
+	s.dispatchConfigMu.RLock()
+	defer s.dispatchConfigMu.RUnlock()
+	return s.dispatchMode, s.overflowPolicy
+}
+
+// Flush blocks until every event this service has enqueued for async
+// dispatch has been processed (handlers run and propagated), or ctx is
+// done. It's a no-op if the service has never dispatched asynchronously.
+func (s *Service) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.dispatchInFlight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) startDispatchWorkers() {
+	s.dispatchStartOnce.Do(func() {
+		s.dispatchConfigMu.RLock()
+		workers := s.dispatchWorkers
+		queueSize := s.dispatchQueueSize
+		s.dispatchConfigMu.RUnlock()
+
+		if workers <= 0 {
+			workers = 1
+		}
+		if queueSize <= 0 {
+			queueSize = defaultDispatchQueueSize
+		}
+
+		s.dispatchQueues = make([]chan EventI, workers)
+		for i := range s.dispatchQueues {
+			queue := make(chan EventI, queueSize)
+			s.dispatchQueues[i] = queue
+			go s.runDispatchWorker(queue)
+		}
+	})
+}
+
+func (s *Service) runDispatchWorker(queue chan EventI) {
+	for event := range queue {
+		s.runHandlersAndPropagate(event)
+		s.dispatchInFlight.Done()
+	}
+}
+
+// workerIdxForVariant shards variants across workers so that all events of a
+// given variant land on the same worker and are handled in the order
+// Dispatch was called, while distinct variants can run concurrently.
+func (s *Service) workerIdxForVariant(variant EventVariant) int {
+	if len(s.dispatchQueues) == 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(variant))
+	return int(h.Sum32() % uint32(len(s.dispatchQueues)))
+}
+
+func (s *Service) dispatchAsync(event EventI, policy OverflowPolicy) error {
+	s.startDispatchWorkers()
+	queue := s.dispatchQueues[s.workerIdxForVariant(event.Variant())]
+
+	s.dispatchInFlight.Add(1)
+	select {
+	case queue <- event:
+		return nil
+	default:
+	}
+
+	switch policy {
+	case Block:
+		queue <- event
+		return nil
+	case DropOldest:
+		select {
+		case dropped := <-queue:
+			s.dispatchInFlight.Done()
+			s.reportDropped(dropped, "queue full: dropped oldest")
+		default:
+		}
+		select {
+		case queue <- event:
+		default:
+			s.dispatchInFlight.Done()
+			s.reportDropped(event, "queue full: dropped incoming after evicting oldest")
+		}
+		return nil
+	case DropNewest:
+		s.dispatchInFlight.Done()
+		s.reportDropped(event, "queue full: dropped newest")
+		return nil
+	case Error:
+		s.dispatchInFlight.Done()
+		s.reportDropped(event, "queue full")
+		return ErrDispatchQueueFull
+	default:
+		s.dispatchInFlight.Done()
+		s.reportDropped(event, "queue full")
+		return nil
+	}
+}
+
+func (s *Service) reportDropped(event EventI, reason string) {
+	for _, h := range s.serviceHandlersSnapshot() {
+		h.OnEventDropped(event, reason)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Async Dispatch", func() {
+	var adderService *AdderService
+	BeforeEach(func() {
+		adderService = CreateServices()
+		adderService.SetDispatchMode(DispatchAsync)
+	})
+
+	It("accepts an async Dispatch and streams the event to subscribers", func() {
+		catcher := newSubscribedCatcher(adderService, "someEvent")
+		defer catcher.sub.Close()
+
+		Expect(adderService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		Expect(catcher.drainNext().Payload()).To(Equal(1))
+	})
+
+	It("Flush blocks until every queued event has been handled", func() {
+		catcher := newSubscribedCatcher(adderService, "someEvent")
+		defer catcher.sub.Close()
+
+		for i := 0; i < 20; i++ {
+			Expect(adderService.Dispatch(NewEvent("someEvent", i))).To(Succeed())
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		Expect(adderService.Flush(ctx)).To(Succeed())
+	})
+
+	It("Flush is a no-op when async dispatch has never run", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		Expect(adderService.Flush(ctx)).To(Succeed())
+	})
+
+	When("the overflow policy is Error", func() {
+		BeforeEach(func() {
+			adderService.SetDispatchWorkers(1)
+			adderService.SetDispatchQueueSize(1)
+			adderService.SetOverflowPolicy(Error)
+		})
+
+		It("returns ErrDispatchQueueFull once the queue is full", func() {
+			var lastErr error
+			for i := 0; i < 50; i++ {
+				if err := adderService.Dispatch(NewEvent("someEvent", i)); err != nil {
+					lastErr = err
+					break
+				}
+			}
+			Expect(lastErr).To(MatchError(ErrDispatchQueueFull))
+		})
+	})
+
+	When("the overflow policy is DropNewest", func() {
+		BeforeEach(func() {
+			adderService.SetDispatchWorkers(1)
+			adderService.SetDispatchQueueSize(1)
+			adderService.SetOverflowPolicy(DropNewest)
+		})
+
+		It("reports the dropped event via OnEventDropped without panicking", func() {
+			handler := &recordingHandler{}
+			adderService.AddServiceHandler(handler)
+
+			for i := 0; i < 50; i++ {
+				Expect(adderService.Dispatch(NewEvent("someEvent", i))).To(Succeed())
+			}
+
+			Eventually(func() []string { return handler.dropped }).ShouldNot(BeEmpty())
+		})
+	})
+
+	When("the overflow policy is DropOldest", func() {
+		BeforeEach(func() {
+			adderService.SetDispatchWorkers(1)
+			adderService.SetDispatchQueueSize(1)
+			adderService.SetOverflowPolicy(DropOldest)
+		})
+
+		It("keeps dispatching without a negative WaitGroup panic", func() {
+			for i := 0; i < 200; i++ {
+				Expect(adderService.Dispatch(NewEvent("someEvent", i))).To(Succeed())
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			Expect(adderService.Flush(ctx)).To(Succeed())
+		})
+	})
+
+	When("the overflow policy is Block", func() {
+		BeforeEach(func() {
+			adderService.SetDispatchWorkers(1)
+			adderService.SetDispatchQueueSize(1)
+			adderService.SetOverflowPolicy(Block)
+		})
+
+		It("eventually delivers every dispatched event", func() {
+			catcher := newSubscribedCatcher(adderService, "someEvent")
+			defer catcher.sub.Close()
+
+			go func() {
+				for i := 0; i < 10; i++ {
+					_ = adderService.Dispatch(NewEvent("someEvent", i))
+				}
+			}()
+
+			for i := 0; i < 10; i++ {
+				Expect(catcher.drainNext().Payload()).To(Equal(i))
+			}
+		})
+	})
+})
@@ -4,6 +4,11 @@ type EventVariant string
 
 const ALL_EVENTS EventVariant = "ALL_EVENTS"
 
+// CONFIG_CHANGED_EVENT is dispatched by SetConfig after registered
+// ServiceHandlers have been notified of the change. Its payload is a
+// *ConfigDiff holding the service's old and new ConfigI.
+const CONFIG_CHANGED_EVENT EventVariant = "CONFIG_CHANGED_EVENT"
+
 type EventI interface {
 	Variant() EventVariant
 	Payload() interface{}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// node is buildGraph's bookkeeping for one service in the dependency DAG.
+// It's rebuilt fresh on every buildGraph call; the idempotency state that
+// must survive across calls (built/started/stopped) lives on the Service
+// itself, see lifecycleController.
+type node struct {
+	service    ServiceI
+	dependsOn  []ServiceI // this service's own dependencies
+	dependents []ServiceI // services that depend on this one
+}
+
+// ServiceGraph is the full transitive dependency graph rooted at the service
+// buildGraph was called on, topologically sorted into waves: every service
+// in waves[i] depends only on services in waves[0:i], so all of waves[i]
+// can run concurrently once waves[0:i] have completed.
+type ServiceGraph struct {
+	nodes map[ServiceI]*node
+	waves [][]ServiceI
+}
+
+// buildGraph walks the full transitive Dependencies() graph rooted at s via
+// an iterative BFS, then topologically sorts it with Kahn's algorithm into
+// leaf-first waves. It returns an error naming the offending cycle if the
+// graph isn't a DAG.
+func (s *Service) buildGraph() (*ServiceGraph, error) {
+	root := s.embeddedIn
+	nodes := make(map[ServiceI]*node)
+	getNode := func(svc ServiceI) *node {
+		n, ok := nodes[svc]
+		if !ok {
+			n = &node{service: svc}
+			nodes[svc] = n
+		}
+		return n
+	}
+
+	queued := map[ServiceI]bool{root: true}
+	queue := []ServiceI{root}
+	getNode(root)
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		currentNode := getNode(current)
+		for _, dep := range current.Dependencies() {
+			depNode := getNode(dep)
+			if !containsService(currentNode.dependsOn, dep) {
+				currentNode.dependsOn = append(currentNode.dependsOn, dep)
+			}
+			if !containsService(depNode.dependents, current) {
+				depNode.dependents = append(depNode.dependents, current)
+			}
+			if !queued[dep] {
+				queued[dep] = true
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	waves, err := topoSortWaves(nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ServiceGraph{nodes: nodes, waves: waves}, nil
+}
+
+func containsService(services []ServiceI, target ServiceI) bool {
+	for _, svc := range services {
+		if svc == target {
+			return true
+		}
+	}
+	return false
+}
+
+// topoSortWaves runs Kahn's algorithm over nodes, grouping each round of
+// zero-remaining-dependency services into its own wave.
+func topoSortWaves(nodes map[ServiceI]*node) ([][]ServiceI, error) {
+	remaining := make(map[ServiceI]int, len(nodes))
+	for svc, n := range nodes {
+		remaining[svc] = len(n.dependsOn)
+	}
+
+	var wave []ServiceI
+	for svc, count := range remaining {
+		if count == 0 {
+			wave = append(wave, svc)
+		}
+	}
+
+	var waves [][]ServiceI
+	processed := 0
+	for len(wave) > 0 {
+		waves = append(waves, wave)
+		processed += len(wave)
+
+		var next []ServiceI
+		for _, svc := range wave {
+			for _, dependent := range nodes[svc].dependents {
+				remaining[dependent]--
+				if remaining[dependent] == 0 {
+					next = append(next, dependent)
+				}
+			}
+		}
+		wave = next
+	}
+
+	if processed != len(nodes) {
+		return nil, fmt.Errorf("service: dependency cycle detected: %s", describeCycle(nodes))
+	}
+
+	return waves, nil
+}
+
+// describeCycle is a best-effort diagnostic: it DFS's the dependsOn edges
+// looking for a back-edge and renders the cycle it finds as a -> b -> ... -> a.
+func describeCycle(nodes map[ServiceI]*node) string {
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := make(map[ServiceI]int, len(nodes))
+	var path []ServiceI
+	var cycle []ServiceI
+
+	var visit func(svc ServiceI) bool
+	visit = func(svc ServiceI) bool {
+		color[svc] = gray
+		path = append(path, svc)
+		for _, dep := range nodes[svc].dependsOn {
+			switch color[dep] {
+			case white:
+				if visit(dep) {
+					return true
+				}
+			case gray:
+				for i, p := range path {
+					if p == dep {
+						cycle = append(append([]ServiceI{}, path[i:]...), dep)
+						break
+					}
+				}
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		color[svc] = black
+		return false
+	}
+
+	for svc := range nodes {
+		if color[svc] == white && visit(svc) {
+			break
+		}
+	}
+
+	if len(cycle) == 0 {
+		return "(cycle detected but could not be isolated)"
+	}
+	names := make([]string, len(cycle))
+	for i, svc := range cycle {
+		names[i] = reflect.TypeOf(svc).String()
+	}
+	out := names[0]
+	for _, name := range names[1:] {
+		out += " -> " + name
+	}
+	return out
+}
+
+// runLifecycleWaves runs fn over every service in each wave concurrently,
+// only advancing to the next wave once the current one has fully completed.
+func runLifecycleWaves(waves [][]ServiceI, fn func(svc ServiceI)) {
+	for _, wave := range waves {
+		var wg sync.WaitGroup
+		wg.Add(len(wave))
+		for _, svc := range wave {
+			go func(svc ServiceI) {
+				defer wg.Done()
+				fn(svc)
+			}(svc)
+		}
+		wg.Wait()
+	}
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// orderParentService/orderChildService let a test observe the wave order
+// Build/Stop run lifecycle hooks in, via injected closures.
+type orderParentService struct {
+	Service
+	Child   *orderChildService
+	onBuild func()
+	onStop  func()
+}
+
+func newOrderParentService() *orderParentService {
+	s := &orderParentService{}
+	s.Service = *NewService(s, nil)
+	return s
+}
+
+func (s *orderParentService) OnBuild() {
+	if s.onBuild != nil {
+		s.onBuild()
+	}
+}
+
+func (s *orderParentService) OnStop() {
+	if s.onStop != nil {
+		s.onStop()
+	}
+}
+
+type orderChildService struct {
+	Service
+	onBuild func()
+	onStop  func()
+}
+
+func newOrderChildService() *orderChildService {
+	s := &orderChildService{}
+	s.Service = *NewService(s, nil)
+	return s
+}
+
+func (s *orderChildService) OnBuild() {
+	if s.onBuild != nil {
+		s.onBuild()
+	}
+}
+
+func (s *orderChildService) OnStop() {
+	if s.onStop != nil {
+		s.onStop()
+	}
+}
+
+// cycleServiceA/cycleServiceB form a two-node cycle via AddDependency, for
+// exercising buildGraph's cycle detection (surfaced through Build/Start/Stop).
+type cycleServiceA struct {
+	Service
+	B *cycleServiceB
+}
+
+func newCycleServiceA() *cycleServiceA {
+	a := &cycleServiceA{}
+	a.Service = *NewService(a, nil)
+	return a
+}
+
+type cycleServiceB struct {
+	Service
+	A *cycleServiceA
+}
+
+func newCycleServiceB() *cycleServiceB {
+	b := &cycleServiceB{}
+	b.Service = *NewService(b, nil)
+	return b
+}
+
+var _ = Describe("buildGraph (via Build/Start/Stop)", func() {
+	It("runs OnBuild leaf-first so a dependency is built before its dependent", func() {
+		parent := newOrderParentService()
+		child := newOrderChildService()
+		parent.AddDependency(child)
+
+		var order []string
+		parent.onBuild = func() { order = append(order, "parent") }
+		child.onBuild = func() { order = append(order, "child") }
+
+		Expect(parent.Build()).To(Succeed())
+
+		Expect(order).To(Equal([]string{"child", "parent"}))
+	})
+
+	It("does not rerun OnBuild for an already-built dependency on a second Build", func() {
+		adderService := CreateServices()
+
+		Expect(adderService.Build()).To(Succeed())
+		Expect(adderService.CutterService.buildCallCount).To(Equal(1))
+
+		Expect(adderService.Build()).To(Succeed())
+		Expect(adderService.CutterService.buildCallCount).To(Equal(1))
+	})
+
+	It("returns an error describing a dependency cycle instead of hanging", func() {
+		a := newCycleServiceA()
+		b := newCycleServiceB()
+		a.AddDependency(b)
+		b.AddDependency(a)
+
+		err := a.Build()
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cycle"))
+	})
+
+	It("runs OnStop in reverse order, dependents before their dependencies", func() {
+		parent := newOrderParentService()
+		child := newOrderChildService()
+		parent.AddDependency(child)
+		Expect(parent.Start()).To(Succeed())
+
+		var order []string
+		parent.onStop = func() { order = append(order, "parent") }
+		child.onStop = func() { order = append(order, "child") }
+
+		Expect(parent.Stop()).To(Succeed())
+
+		Expect(order).To(Equal([]string{"parent", "child"}))
+	})
+})
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Propagator decides where an event goes after a service's own handlers
+// have run and decided to propagate it (see Dispatch). It generalizes
+// PropagateEvent from strictly "send to parent" into pluggable routing
+// strategies, while ParentPropagator preserves the original behavior as the
+// default.
+type Propagator interface {
+	Propagate(s ServiceI, ev EventI)
+}
+
+// propagationTrail wraps an event as a Propagator fans it out, recording
+// every service that has already received it. A service further down the
+// chain (e.g. a SubtreePropagator's descendant whose own default
+// ParentPropagator would otherwise send the event straight back up) can
+// then skip services already on the trail instead of re-propagating
+// forever. Once built, a propagationTrail's visited map is never mutated -
+// a fan-out Propagator (SubtreePropagator, BroadcastPropagator) hands the
+// same trail to several targets whose own propagation may then run
+// concurrently on separate dispatch workers, so mutating one shared map
+// from all of them would race.
+type propagationTrail struct {
+	EventI
+	visited map[ServiceI]bool
+}
+
+// markPropagated returns a copy of ev's trail (starting a new one if ev
+// doesn't have one yet) with from and to additionally marked as seen. It
+// never mutates ev's own trail in place - see propagationTrail - so it's
+// safe to call concurrently with other markPropagated calls built from the
+// same incoming trail, as happens when a fan-out Propagator hands the same
+// ev to multiple targets that each propagate further on their own
+// goroutine.
+func markPropagated(ev EventI, from, to ServiceI) EventI {
+	var underlying EventI = ev
+	var base map[ServiceI]bool
+	if trail, ok := ev.(*propagationTrail); ok {
+		underlying = trail.EventI
+		base = trail.visited
+	}
+
+	visited := make(map[ServiceI]bool, len(base)+2)
+	for svc, seen := range base {
+		visited[svc] = seen
+	}
+	visited[from] = true
+	visited[to] = true
+
+	return &propagationTrail{EventI: underlying, visited: visited}
+}
+
+// alreadyPropagated reports whether ev's trail (if any) already reached s.
+func alreadyPropagated(ev EventI, s ServiceI) bool {
+	trail, ok := ev.(*propagationTrail)
+	if !ok {
+		return false
+	}
+	return trail.visited[s]
+}
+
+// ParentPropagator sends ev to s's parent, same as the hardcoded behavior
+// Propagator replaced. It's the default for every Service.
+type ParentPropagator struct{}
+
+func (ParentPropagator) Propagate(s ServiceI, ev EventI) {
+	parent := s.Parent()
+	if parent == nil || alreadyPropagated(ev, parent) {
+		return
+	}
+	_ = parent.Dispatch(markPropagated(ev, s, parent))
+}
+
+// BroadcastPropagator sends ev to every other dependency of s's parent (s's
+// siblings), rather than to the parent itself.
+type BroadcastPropagator struct{}
+
+func (BroadcastPropagator) Propagate(s ServiceI, ev EventI) {
+	for _, sibling := range siblingsOf(s) {
+		if alreadyPropagated(ev, sibling) {
+			continue
+		}
+		_ = sibling.Dispatch(markPropagated(ev, s, sibling))
+	}
+}
+
+// SubtreePropagator fans ev out to every transitive dependency of s, rather
+// than up to its parent.
+type SubtreePropagator struct{}
+
+func (SubtreePropagator) Propagate(s ServiceI, ev EventI) {
+	visited := map[ServiceI]bool{s: true}
+	queue := append([]ServiceI{}, s.Dependencies()...)
+	for len(queue) > 0 {
+		next := queue[0]
+		queue = queue[1:]
+		if visited[next] || alreadyPropagated(ev, next) {
+			continue
+		}
+		visited[next] = true
+		_ = next.Dispatch(markPropagated(ev, s, next))
+		queue = append(queue, next.Dependencies()...)
+	}
+}
+
+// LoadBalancedPropagator builds a Propagator that routes ev to exactly one
+// of s's siblings, chosen by selector, instead of broadcasting to all of
+// them. This mirrors a node-selector that round-robins (or otherwise picks)
+// across a set of service instances.
+func LoadBalancedPropagator(selector func([]ServiceI) ServiceI) Propagator {
+	return &loadBalancedPropagator{selector: selector}
+}
+
+type loadBalancedPropagator struct {
+	selector func([]ServiceI) ServiceI
+}
+
+func (p *loadBalancedPropagator) Propagate(s ServiceI, ev EventI) {
+	peers := siblingsOf(s)
+	if len(peers) == 0 {
+		return
+	}
+	target := p.selector(peers)
+	if target == nil || alreadyPropagated(ev, target) {
+		return
+	}
+	_ = target.Dispatch(markPropagated(ev, s, target))
+}
+
+func siblingsOf(s ServiceI) []ServiceI {
+	parent := s.Parent()
+	if parent == nil {
+		return nil
+	}
+	var siblings []ServiceI
+	for _, dep := range parent.Dependencies() {
+		if dep == s {
+			continue
+		}
+		siblings = append(siblings, dep)
+	}
+	return siblings
+}
+
+// SetPropagator installs the strategy Dispatch uses to route events this
+// service's handlers chose to propagate. Defaults to ParentPropagator.
+func (s *Service) SetPropagator(p Propagator) {
+	s.propagatorMu.Lock()
+	defer s.propagatorMu.Unlock()
+	s.propagator = p
+}
+
+// Route dispatches ev directly to the service named target, found by
+// walking up to the root of the tree and back down through Dependencies(),
+// so a leaf service can reach a named sibling or uncle without holding a
+// reference to it. The name matched is the dependency's concrete type name,
+// the same one AddDependency infers the parent's field from.
+func (s *Service) Route(target string, ev EventI) error {
+	root := s.embeddedIn
+	for {
+		parent := root.Parent()
+		if parent == nil {
+			break
+		}
+		root = parent
+	}
+
+	found := findServiceByName(root, target, make(map[ServiceI]bool))
+	if found == nil {
+		return fmt.Errorf("service: no service named %q found in the tree", target)
+	}
+	return found.Dispatch(ev)
+}
+
+func findServiceByName(svc ServiceI, name string, visited map[ServiceI]bool) ServiceI {
+	if visited[svc] {
+		return nil
+	}
+	visited[svc] = true
+
+	if reflect.TypeOf(svc).Elem().Name() == name {
+		return svc
+	}
+	for _, dep := range svc.Dependencies() {
+		if found := findServiceByName(dep, name, visited); found != nil {
+			return found
+		}
+	}
+	return nil
+}
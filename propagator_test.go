@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// Fixtures for the async fan-out regression test below: Root depends on Mid,
+// Sib1, and Sib2; Mid depends on Leaf. Leaf forwards up to Mid via the
+// default ParentPropagator, Mid broadcasts to its siblings Sib1/Sib2, and
+// Sib1/Sib2 each forward up to Root on their own async worker - two trails
+// copied from the same incoming trail, forwarded concurrently.
+type RaceLeafService struct{ Service }
+
+func NewRaceLeafService() *RaceLeafService {
+	s := &RaceLeafService{}
+	s.Service = *NewService(s, nil)
+	return s
+}
+
+type RaceMidService struct {
+	Service
+	RaceLeafService *RaceLeafService
+}
+
+func NewRaceMidService() *RaceMidService {
+	s := &RaceMidService{}
+	s.Service = *NewService(s, nil)
+	return s
+}
+
+type RaceSib1Service struct{ Service }
+
+func NewRaceSib1Service() *RaceSib1Service {
+	s := &RaceSib1Service{}
+	s.Service = *NewService(s, nil)
+	return s
+}
+
+type RaceSib2Service struct{ Service }
+
+func NewRaceSib2Service() *RaceSib2Service {
+	s := &RaceSib2Service{}
+	s.Service = *NewService(s, nil)
+	return s
+}
+
+type RaceRootService struct {
+	Service
+	RaceMidService  *RaceMidService
+	RaceSib1Service *RaceSib1Service
+	RaceSib2Service *RaceSib2Service
+}
+
+func NewRaceRootService() *RaceRootService {
+	s := &RaceRootService{}
+	s.Service = *NewService(s, nil)
+	return s
+}
+
+var _ = Describe("Propagators", func() {
+	var adderService *AdderService
+	BeforeEach(func() {
+		adderService = CreateServices()
+	})
+
+	It("ParentPropagator (the default) sends the event to the parent", func() {
+		catcher := newSubscribedCatcher(adderService, "someEvent")
+		defer catcher.sub.Close()
+
+		Expect(adderService.CutterService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		Expect(catcher.drainNext().Payload()).To(Equal(1))
+	})
+
+	It("BroadcastPropagator sends the event to every sibling, not directly to the parent", func() {
+		adderService.CutterService.SetPropagator(BroadcastPropagator{})
+
+		otherCatcher := newSubscribedCatcher(adderService.OtherSubService, "someEvent")
+		defer otherCatcher.sub.Close()
+		strangeCatcher := newSubscribedCatcher(adderService.StrangelyNamedServiceField, "someEvent")
+		defer strangeCatcher.sub.Close()
+
+		Expect(adderService.CutterService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		Expect(otherCatcher.drainNext().Payload()).To(Equal(1))
+		Expect(strangeCatcher.drainNext().Payload()).To(Equal(1))
+	})
+
+	It("SubtreePropagator sends the event to every transitive dependency", func() {
+		adderService.SetPropagator(SubtreePropagator{})
+
+		cutterCatcher := newSubscribedCatcher(adderService.CutterService, "someEvent")
+		defer cutterCatcher.sub.Close()
+		otherCatcher := newSubscribedCatcher(adderService.OtherSubService, "someEvent")
+		defer otherCatcher.sub.Close()
+		strangeCatcher := newSubscribedCatcher(adderService.StrangelyNamedServiceField, "someEvent")
+		defer strangeCatcher.sub.Close()
+
+		Expect(adderService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		Expect(cutterCatcher.drainNext().Payload()).To(Equal(1))
+		Expect(otherCatcher.drainNext().Payload()).To(Equal(1))
+		Expect(strangeCatcher.drainNext().Payload()).To(Equal(1))
+	})
+
+	It("does not loop forever when a descendant's default ParentPropagator would send the event back up", func() {
+		adderService.SetPropagator(SubtreePropagator{})
+
+		rootCatcher := newSubscribedCatcher(adderService, "someEvent")
+		defer rootCatcher.sub.Close()
+
+		Expect(adderService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		// The root only sees the event it dispatched itself, never a bounce
+		// back from one of its children's default ParentPropagator.
+		Expect(rootCatcher.drainNext().Payload()).To(Equal(1))
+		Consistently(rootCatcher.sub.Events()).ShouldNot(Receive())
+	})
+
+	It("LoadBalancedPropagator routes to exactly the sibling the selector picks", func() {
+		adderService.CutterService.SetPropagator(LoadBalancedPropagator(func(peers []ServiceI) ServiceI {
+			for _, p := range peers {
+				if p == ServiceI(adderService.StrangelyNamedServiceField) {
+					return p
+				}
+			}
+			return nil
+		}))
+
+		otherCatcher := newSubscribedCatcher(adderService.OtherSubService, "someEvent")
+		defer otherCatcher.sub.Close()
+		strangeCatcher := newSubscribedCatcher(adderService.StrangelyNamedServiceField, "someEvent")
+		defer strangeCatcher.sub.Close()
+
+		Expect(adderService.CutterService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		Expect(strangeCatcher.drainNext().Payload()).To(Equal(1))
+		Consistently(otherCatcher.sub.Events()).ShouldNot(Receive())
+	})
+
+	It("does not race when a shared trail forwards through two concurrent async siblings (regression)", func() {
+		root := NewRaceRootService()
+		mid := NewRaceMidService()
+		leaf := NewRaceLeafService()
+		sib1 := NewRaceSib1Service()
+		sib2 := NewRaceSib2Service()
+
+		root.AddDependency(mid)
+		root.AddDependency(sib1)
+		root.AddDependency(sib2)
+		mid.AddDependency(leaf)
+
+		mid.SetPropagator(BroadcastPropagator{})
+		sib1.SetDispatchMode(DispatchAsync)
+		sib2.SetDispatchMode(DispatchAsync)
+
+		rootCatcher := newSubscribedCatcher(root, "someEvent")
+		defer rootCatcher.sub.Close()
+
+		Expect(leaf.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		Expect(sib1.Flush(context.Background())).To(Succeed())
+		Expect(sib2.Flush(context.Background())).To(Succeed())
+
+		Eventually(rootCatcher.sub.Events()).Should(Receive())
+	})
+
+	Describe("Route", func() {
+		It("dispatches directly to a named service anywhere in the tree", func() {
+			catcher := newSubscribedCatcher(adderService.StrangelyNamedServiceField, "someEvent")
+			defer catcher.sub.Close()
+
+			Expect(adderService.CutterService.Route("StrangeService", NewEvent("someEvent", 1))).To(Succeed())
+
+			Expect(catcher.drainNext().Payload()).To(Equal(1))
+		})
+
+		It("errors when no service with that name exists in the tree", func() {
+			err := adderService.Route("NoSuchService", NewEvent("someEvent", 1))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"reflect"
+	"sync"
 )
 
 type ServiceI interface {
@@ -9,12 +11,27 @@ type ServiceI interface {
 	Dependencies() []ServiceI
 	AddDependency(service ServiceI)
 	OnBuild()
-	Build()
+	Build() error
 	OnStart()
-	Start()
-	Dispatch(event EventI)
+	Start() error
+	OnStop()
+	Stop() error
+	Dispatch(event EventI) error
 	AddEventListener(eventVariant EventVariant, fn EventHandler) (eventId int)
 	RemoveEventListener(eventId int)
+	Subscribe(req SubscribeRequest) (*Subscription, error)
+	AddServiceHandler(h ServiceHandler) (handlerId int)
+	RemoveDependency(dep ServiceI)
+	SetConfig(config ConfigI)
+	LoadConfig(sources ...ConfigSource) error
+	SetDispatchMode(mode DispatchMode)
+	SetDispatchWorkers(n int)
+	SetDispatchQueueSize(n int)
+	SetOverflowPolicy(policy OverflowPolicy)
+	Flush(ctx context.Context) error
+	SetPropagator(p Propagator)
+	Route(target string, ev EventI) error
+	Parent() ServiceI
 	SetParent(parent ServiceI)
 }
 
@@ -22,10 +39,35 @@ type Service struct {
 	parent                   ServiceI
 	embeddedIn               ServiceI
 	config                   ConfigI
+	eventHandlersMu          sync.RWMutex
 	eventHandlersCount       int
 	variantByEventId         map[int]EventVariant
 	eventHandlerIdxByEventId map[int]int
 	eventHandlersByVariant   map[EventVariant][]EventHandler
+
+	topicsMu             sync.Mutex
+	eventRingsByTopic    map[EventVariant]*eventRing
+	snapshotFuncsByTopic map[EventVariant]SnapshotFunc
+
+	serviceHandlersMu sync.RWMutex
+	serviceHandlers   []ServiceHandler
+
+	lifecycleMu sync.Mutex
+	built       bool
+	started     bool
+	stopped     bool
+
+	dispatchConfigMu  sync.RWMutex
+	dispatchMode      DispatchMode
+	dispatchWorkers   int
+	dispatchQueueSize int
+	overflowPolicy    OverflowPolicy
+	dispatchStartOnce sync.Once
+	dispatchQueues    []chan EventI
+	dispatchInFlight  sync.WaitGroup
+
+	propagatorMu sync.RWMutex
+	propagator   Propagator
 }
 
 func (s *Service) Config() ConfigI {
@@ -41,48 +83,157 @@ func NewService(service ServiceI, config ConfigI) *Service {
 		variantByEventId:         make(map[int]EventVariant),
 		eventHandlerIdxByEventId: make(map[int]int),
 		eventHandlersByVariant:   make(map[EventVariant][]EventHandler),
+		eventRingsByTopic:        make(map[EventVariant]*eventRing),
+		snapshotFuncsByTopic:     make(map[EventVariant]SnapshotFunc),
+		propagator:               ParentPropagator{},
 	}
 }
 
 func (s *Service) OnBuild() {}
 
-func (s *Service) Build() {
-	// NOTE: Do not override (only implement OnBuild)
-	s.embeddedIn.OnBuild()
-	dependencies := s.Dependencies()
-	for _, dep := range dependencies {
-		dep.Build()
+// Build constructs the DAG of this service and its transitive dependencies
+// and runs OnBuild in parallel waves from the leaves up, so a service is
+// never built before a dependency it relies on. Each service is built at
+// most once: one reached twice via a diamond dependency is a single node in
+// the graph, and one already built by an earlier Build() or Start() call is
+// skipped. See buildGraph for the graph construction.
+//
+// NOTE: Do not override (only implement OnBuild)
+func (s *Service) Build() error {
+	graph, err := s.buildGraph()
+	if err != nil {
+		return err
 	}
+	runLifecycleWaves(graph.waves, func(svc ServiceI) {
+		if svc.(lifecycleController).tryMarkBuilt() {
+			svc.OnBuild()
+		}
+	})
+	return nil
 }
 
 func (s *Service) OnStart() {}
 
-func (s *Service) Start() {
-	// NOTE: Do not override (only implement OnStart)
-	s.embeddedIn.OnStart()
-	dependencies := s.Dependencies()
-	for _, dep := range dependencies {
-		dep.Start()
+// Start builds (if not already built) and then starts this service and its
+// transitive dependencies, running OnStart in the same leaf-up waves as
+// Build.
+//
+// NOTE: Do not override (only implement OnStart)
+func (s *Service) Start() error {
+	graph, err := s.buildGraph()
+	if err != nil {
+		return err
+	}
+	runLifecycleWaves(graph.waves, func(svc ServiceI) {
+		if svc.(lifecycleController).tryMarkBuilt() {
+			svc.OnBuild()
+		}
+	})
+	runLifecycleWaves(graph.waves, func(svc ServiceI) {
+		if svc.(lifecycleController).tryMarkStarted() {
+			svc.OnStart()
+		}
+	})
+	return nil
+}
+
+func (s *Service) OnStop() {}
+
+// Stop runs OnStop over this service and its transitive dependencies in
+// reverse topological order (dependents before the dependencies they rely
+// on), so a service can still use its dependencies while it's shutting down.
+//
+// NOTE: Do not override (only implement OnStop)
+func (s *Service) Stop() error {
+	graph, err := s.buildGraph()
+	if err != nil {
+		return err
+	}
+	reversed := make([][]ServiceI, len(graph.waves))
+	for i, wave := range graph.waves {
+		reversed[len(graph.waves)-1-i] = wave
+	}
+	runLifecycleWaves(reversed, func(svc ServiceI) {
+		if svc.(lifecycleController).tryMarkStopped() {
+			svc.OnStop()
+		}
+	})
+	return nil
+}
+
+// lifecycleController is implemented by *Service (and promoted to anything
+// embedding it) to guard Build/Start/Stop against running a lifecycle hook
+// more than once for the same service.
+type lifecycleController interface {
+	tryMarkBuilt() bool
+	tryMarkStarted() bool
+	tryMarkStopped() bool
+}
+
+func (s *Service) tryMarkBuilt() bool {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	if s.built {
+		return false
 	}
+	s.built = true
+	return true
 }
 
-func (s *Service) Dispatch(event EventI) {
+func (s *Service) tryMarkStarted() bool {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	if s.started {
+		return false
+	}
+	s.started = true
+	return true
+}
+
+func (s *Service) tryMarkStopped() bool {
+	s.lifecycleMu.Lock()
+	defer s.lifecycleMu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.stopped = true
+	return true
+}
+
+// Dispatch runs event through this service's handlers and, in DispatchSync
+// mode (the default), propagates it before returning. In DispatchAsync mode
+// it enqueues the event for a worker to handle instead; see
+// SetDispatchMode, SetDispatchWorkers, SetDispatchQueueSize, and
+// SetOverflowPolicy.
+func (s *Service) Dispatch(event EventI) error {
+	s.ringForTopic(event.Variant()).append(event)
+
+	mode, policy := s.dispatchModeAndPolicy()
+	if mode == DispatchSync {
+		s.runHandlersAndPropagate(event)
+		return nil
+	}
+	return s.dispatchAsync(event, policy)
+}
+
+func (s *Service) runHandlersAndPropagate(event EventI) {
+	s.eventHandlersMu.RLock()
+	variantHandlers := append([]EventHandler(nil), s.eventHandlersByVariant[event.Variant()]...)
+	allHandlers := append([]EventHandler(nil), s.eventHandlersByVariant[ALL_EVENTS]...)
+	s.eventHandlersMu.RUnlock()
+
 	willPropagate := true
-	if eventHandlers, ok := s.eventHandlersByVariant[event.Variant()]; ok {
-		for _, eventHandler := range eventHandlers {
-			if eventHandler == nil {
-				continue
-			}
-			willPropagate = willPropagate && eventHandler(event)
+	for _, eventHandler := range variantHandlers {
+		if eventHandler == nil {
+			continue
 		}
+		willPropagate = willPropagate && eventHandler(s.embeddedIn, event)
 	}
-	if eventHandlers, ok := s.eventHandlersByVariant[ALL_EVENTS]; ok {
-		for _, eventHandler := range eventHandlers {
-			if eventHandler == nil {
-				continue
-			}
-			willPropagate = willPropagate && eventHandler(event)
+	for _, eventHandler := range allHandlers {
+		if eventHandler == nil {
+			continue
 		}
+		willPropagate = willPropagate && eventHandler(s.embeddedIn, event)
 	}
 	if willPropagate {
 		s.PropagateEvent(event)
@@ -148,9 +299,39 @@ func (s *Service) AddDependency(dep ServiceI) {
 
 	// set the dependency on this service
 	parValField.Set(reflect.ValueOf(dep))
+
+	for _, h := range s.serviceHandlersSnapshot() {
+		h.OnDependencyAdded(dep)
+	}
+}
+
+// RemoveDependency clears the field on this service holding dep (as set by
+// AddDependency), unsets dep's parent, and notifies registered
+// ServiceHandlers.
+func (s *Service) RemoveDependency(dep ServiceI) {
+	parVal := reflect.ValueOf(s.embeddedIn).Elem()
+	for i := 0; i < parVal.NumField(); i++ {
+		fieldVal := parVal.Field(i)
+		if !fieldVal.CanInterface() {
+			continue
+		}
+		if fieldVal.Interface() == dep {
+			fieldVal.Set(reflect.Zero(fieldVal.Type()))
+			break
+		}
+	}
+
+	dep.SetParent(nil)
+
+	for _, h := range s.serviceHandlersSnapshot() {
+		h.OnDependencyRemoved(dep)
+	}
 }
 
 func (s *Service) AddEventListener(eventVariant EventVariant, fn EventHandler) (eventId int) {
+	s.eventHandlersMu.Lock()
+	defer s.eventHandlersMu.Unlock()
+
 	eventId = s.eventHandlersCount
 	s.eventHandlersCount++
 	if _, ok := s.eventHandlersByVariant[eventVariant]; !ok {
@@ -163,7 +344,12 @@ func (s *Service) AddEventListener(eventVariant EventVariant, fn EventHandler) (
 	return eventId
 }
 
+// RemoveEventListener is safe to call concurrently with AddEventListener and
+// Dispatch, including from inside a handler that's currently running.
 func (s *Service) RemoveEventListener(eventId int) {
+	s.eventHandlersMu.Lock()
+	defer s.eventHandlersMu.Unlock()
+
 	variant, ok := s.variantByEventId[eventId]
 	if !ok {
 		return
@@ -177,11 +363,78 @@ func (s *Service) RemoveEventListener(eventId int) {
 	delete(s.eventHandlerIdxByEventId, eventId)
 }
 
-func (s *Service) PropagateEvent(event EventI) {
-	if s.parent == nil {
-		return
+func (s *Service) AddServiceHandler(h ServiceHandler) (handlerId int) {
+	s.serviceHandlersMu.Lock()
+	defer s.serviceHandlersMu.Unlock()
+
+	handlerId = len(s.serviceHandlers)
+	s.serviceHandlers = append(s.serviceHandlers, h)
+	return handlerId
+}
+
+// serviceHandlersSnapshot returns a copy of the registered ServiceHandlers,
+// so callers can run handlers without holding serviceHandlersMu while they
+// do (a handler that calls AddServiceHandler itself would otherwise
+// deadlock).
+func (s *Service) serviceHandlersSnapshot() []ServiceHandler {
+	s.serviceHandlersMu.RLock()
+	defer s.serviceHandlersMu.RUnlock()
+	return append([]ServiceHandler(nil), s.serviceHandlers...)
+}
+
+// ConfigDiff is the payload of a CONFIG_CHANGED_EVENT, pairing the config a
+// service had before SetConfig/LoadConfig with what it changed to.
+type ConfigDiff struct {
+	Old ConfigI
+	New ConfigI
+}
+
+// SetConfig merges config on top of the service's current config, notifies
+// registered ServiceHandlers of the change, then dispatches a
+// CONFIG_CHANGED_EVENT carrying the ConfigDiff.
+func (s *Service) SetConfig(config ConfigI) {
+	old := s.config
+	merged := config.MergeWith(old)
+	s.applyConfig(old, merged)
+}
+
+// LoadConfig reduces sources left-to-right on top of the service's current
+// config (see the package-level LoadConfig) and applies the result the same
+// way SetConfig does: notifying ServiceHandlers and dispatching a
+// CONFIG_CHANGED_EVENT.
+func (s *Service) LoadConfig(sources ...ConfigSource) error {
+	old := s.config
+	merged, err := LoadConfig(old, sources...)
+	if err != nil {
+		return err
+	}
+	s.applyConfig(old, merged)
+	return nil
+}
+
+func (s *Service) applyConfig(old, merged ConfigI) {
+	s.config = merged
+
+	for _, h := range s.serviceHandlersSnapshot() {
+		h.OnConfigChange(old, merged)
 	}
-	s.parent.(ServiceI).Dispatch(event)
+
+	_ = s.Dispatch(NewEvent(CONFIG_CHANGED_EVENT, &ConfigDiff{Old: old, New: merged}))
+}
+
+// PropagateEvent hands event off to this service's Propagator (see
+// SetPropagator). By default, via ParentPropagator, that's the same "send to
+// parent" behavior this method used to hardcode.
+func (s *Service) PropagateEvent(event EventI) {
+	s.propagatorMu.RLock()
+	propagator := s.propagator
+	s.propagatorMu.RUnlock()
+
+	propagator.Propagate(s.embeddedIn, event)
+}
+
+func (s *Service) Parent() ServiceI {
+	return s.parent
 }
 
 func (s *Service) SetParent(parent ServiceI) {
@@ -0,0 +1,16 @@
+package service
+
+// ServiceHandler lets a caller react to structural changes on a Service with
+// strongly typed callbacks, instead of subscribing to generic Events and
+// type-asserting their payloads. Handlers are notified synchronously, in the
+// order add -> update -> delete, from AddDependency, SetConfig, and
+// RemoveDependency respectively.
+type ServiceHandler interface {
+	OnConfigChange(old, new ConfigI)
+	OnDependencyAdded(dep ServiceI)
+	OnDependencyRemoved(dep ServiceI)
+	// OnEventDropped reports an event that async Dispatch could not queue,
+	// per the service's OverflowPolicy. reason is a short human-readable
+	// description, e.g. "queue full: dropped oldest".
+	OnEventDropped(ev EventI, reason string)
+}
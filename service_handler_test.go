@@ -0,0 +1,116 @@
+package service
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// hcConfig is a minimal ConfigI whose MergeWith actually lets override win,
+// unlike AdderConfig/CutterConfig's test doubles (which just copy whichever
+// config is passed as the MergeWith argument) - needed here to assert on the
+// old/new values SetConfig hands to OnConfigChange and ConfigDiff.
+type hcConfig struct {
+	Value int
+}
+
+func (c *hcConfig) MergeWith(ConfigI) ConfigI {
+	merged := *c
+	return &merged
+}
+
+type hcService struct {
+	Service
+}
+
+func newHCService(cfg *hcConfig) *hcService {
+	s := &hcService{}
+	s.Service = *NewService(s, cfg)
+	return s
+}
+
+// recordingHandler implements ServiceHandler and records every call it
+// receives, for asserting on call order and arguments.
+type recordingHandler struct {
+	configChanges []ConfigDiff
+	added         []ServiceI
+	removed       []ServiceI
+	dropped       []string
+}
+
+func (h *recordingHandler) OnConfigChange(old, new ConfigI) {
+	h.configChanges = append(h.configChanges, ConfigDiff{Old: old, New: new})
+}
+
+func (h *recordingHandler) OnDependencyAdded(dep ServiceI) {
+	h.added = append(h.added, dep)
+}
+
+func (h *recordingHandler) OnDependencyRemoved(dep ServiceI) {
+	h.removed = append(h.removed, dep)
+}
+
+func (h *recordingHandler) OnEventDropped(ev EventI, reason string) {
+	h.dropped = append(h.dropped, reason)
+}
+
+var _ = Describe("ServiceHandler", func() {
+	var adderService *AdderService
+	var handler *recordingHandler
+	BeforeEach(func() {
+		adderService = NewAdderService(&AdderConfig{})
+		handler = &recordingHandler{}
+		adderService.AddServiceHandler(handler)
+	})
+
+	It("notifies OnDependencyAdded when a dependency is added", func() {
+		cutterService := NewCutterService(&CutterConfig{})
+		adderService.AddDependency(cutterService)
+		Expect(handler.added).To(ConsistOf(ServiceI(cutterService)))
+	})
+
+	It("notifies OnDependencyRemoved when a dependency is removed", func() {
+		cutterService := NewCutterService(&CutterConfig{})
+		adderService.AddDependency(cutterService)
+		adderService.RemoveDependency(cutterService)
+		Expect(handler.removed).To(ConsistOf(ServiceI(cutterService)))
+		Expect(adderService.CutterService).To(BeNil())
+		Expect(cutterService.parent).To(BeNil())
+	})
+
+	It("notifies OnConfigChange with the old and new config on SetConfig", func() {
+		hcSvc := newHCService(&hcConfig{Value: 1})
+		hcSvc.AddServiceHandler(handler)
+
+		hcSvc.SetConfig(&hcConfig{Value: 5})
+
+		Expect(handler.configChanges).To(HaveLen(1))
+		Expect(handler.configChanges[0].Old.(*hcConfig).Value).To(Equal(1))
+		Expect(handler.configChanges[0].New.(*hcConfig).Value).To(Equal(5))
+	})
+
+	It("dispatches a CONFIG_CHANGED_EVENT carrying a *ConfigDiff", func() {
+		hcSvc := newHCService(&hcConfig{Value: 1})
+		catcher := newSubscribedCatcher(hcSvc, CONFIG_CHANGED_EVENT)
+		defer catcher.sub.Close()
+
+		hcSvc.SetConfig(&hcConfig{Value: 7})
+
+		ev := catcher.drainNext()
+		diff, ok := ev.Payload().(*ConfigDiff)
+		Expect(ok).To(BeTrue())
+		Expect(diff.New.(*hcConfig).Value).To(Equal(7))
+	})
+
+	It("notifies OnEventDropped when async dispatch can't enqueue", func() {
+		adderService.SetDispatchMode(DispatchAsync)
+		adderService.SetDispatchWorkers(1)
+		adderService.SetDispatchQueueSize(1)
+		adderService.SetOverflowPolicy(DropNewest)
+
+		for i := 0; i < 10; i++ {
+			Expect(adderService.Dispatch(NewEvent("someEvent", i))).To(Succeed())
+		}
+
+		Eventually(func() []string { return handler.dropped }).ShouldNot(BeEmpty())
+	})
+})
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"sync"
+)
+
+// Payload lets a dispatched event's payload opt into key-based filtering for
+// Subscribe. Payloads that don't implement Payload match every subscription's
+// key, e.g. EventPayloadCheckServiceNode.MatchesKey in downstream services.
+type Payload interface {
+	MatchesKey(key string) bool
+}
+
+// SubscribeRequest describes what a caller wants to stream from Subscribe.
+// Key filters the stream to events whose Payload.MatchesKey(Key) returns true,
+// and is ignored for payloads that don't implement Payload. Index resumes the
+// stream after a prior snapshot/event index instead of snapshotting again.
+type SubscribeRequest struct {
+	Topic EventVariant
+	Key   string
+	Index uint64
+}
+
+// SnapshotFunc seeds a new Subscription with its initial state before the
+// subscription switches over to live events. It should call append for every
+// event in the snapshot and return the index live events should resume after.
+type SnapshotFunc func(req SubscribeRequest, append func(EventI)) (uint64, error)
+
+// CAUGHT_UP_EVENT is dispatched to a Subscription's channel, in place of the
+// ring buffer's evicted events, when a subscriber falls too far behind the
+// topic's ring buffer to replay. The subscriber should re-Subscribe to
+// receive a fresh snapshot.
+const CAUGHT_UP_EVENT EventVariant = "CAUGHT_UP_EVENT"
+
+// ErrCaughtUp is the payload of a CAUGHT_UP_EVENT.
+type ErrCaughtUp struct {
+	Topic EventVariant
+}
+
+func (e *ErrCaughtUp) MatchesKey(_ string) bool {
+	return true
+}
+
+// defaultRingSize bounds how many events per topic a Service retains for
+// subscribers to replay. Older events are evicted on overflow.
+const defaultRingSize = 1024
+
+type ringEntry struct {
+	index uint64
+	event EventI
+}
+
+type eventRing struct {
+	mu      sync.Mutex
+	entries []ringEntry
+	head    int
+	size    int
+	nextIdx uint64
+	// notifyCh is closed and replaced on every append, so a waiter parked on
+	// it in since wakes as soon as there's something new to check, without
+	// needing a sync.Cond (which can't be combined with a select on closed).
+	notifyCh chan struct{}
+}
+
+func newEventRing(cap int) *eventRing {
+	return &eventRing{
+		entries: make([]ringEntry, cap),
+		// Indices start at 1 so 0 can mean "before any event" - both the
+		// zero-value SubscribeRequest.Index and an empty ring's tail - without
+		// colliding with a real event's index.
+		nextIdx:  1,
+		notifyCh: make(chan struct{}),
+	}
+}
+
+func (r *eventRing) append(ev EventI) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	idx := r.nextIdx
+	r.nextIdx++
+	pos := (r.head + r.size) % len(r.entries)
+	r.entries[pos] = ringEntry{index: idx, event: ev}
+	if r.size < len(r.entries) {
+		r.size++
+	} else {
+		r.head = (r.head + 1) % len(r.entries)
+	}
+	close(r.notifyCh)
+	r.notifyCh = make(chan struct{})
+	return idx
+}
+
+// oldestIndex returns the index of the oldest entry still retained, and
+// whether the ring holds any entries at all.
+func (r *eventRing) oldestIndex() (uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size == 0 {
+		return 0, false
+	}
+	return r.entries[r.head].index, true
+}
+
+// currentTail returns the index of the most recently appended entry, or 0 if
+// the ring is empty. A fresh subscriber can pass this as its starting index
+// to receive only events appended from now on.
+func (r *eventRing) currentTail() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.nextIdx - 1
+}
+
+// since blocks until an entry with index > after exists (or closed is
+// closed), then returns it. ok is false, with caughtUp true, if after has
+// already fallen behind the oldest retained entry.
+func (r *eventRing) since(after uint64, closed <-chan struct{}) (ev EventI, idx uint64, caughtUp bool, ok bool) {
+	for {
+		r.mu.Lock()
+		if r.size > 0 {
+			oldest := r.entries[r.head].index
+			if after+1 < oldest {
+				r.mu.Unlock()
+				return nil, 0, true, false
+			}
+			newest := r.entries[(r.head+r.size-1)%len(r.entries)].index
+			if after < newest {
+				offset := after + 1 - oldest
+				entry := r.entries[(r.head+int(offset))%len(r.entries)]
+				r.mu.Unlock()
+				return entry.event, entry.index, false, true
+			}
+		}
+		waitCh := r.notifyCh
+		r.mu.Unlock()
+		select {
+		case <-waitCh:
+		case <-closed:
+			return nil, 0, false, false
+		}
+	}
+}
+
+// Subscription streams events for a single SubscribeRequest: a snapshot (if a
+// SnapshotFunc is registered for the topic) followed by live events.
+type Subscription struct {
+	events    chan EventI
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+func (sub *Subscription) Events() <-chan EventI {
+	return sub.events
+}
+
+func (sub *Subscription) Close() {
+	sub.closeOnce.Do(func() {
+		close(sub.closeCh)
+	})
+}
+
+// RegisterTopic associates a SnapshotFunc with a topic so future Subscribe
+// calls for that topic are seeded with a snapshot before streaming live
+// events. Passing a nil snapshotFn subscribes callers straight into live
+// events.
+func (s *Service) RegisterTopic(topic EventVariant, snapshotFn SnapshotFunc) {
+	s.topicsMu.Lock()
+	defer s.topicsMu.Unlock()
+	s.snapshotFuncsByTopic[topic] = snapshotFn
+}
+
+func (s *Service) ringForTopic(topic EventVariant) *eventRing {
+	s.topicsMu.Lock()
+	defer s.topicsMu.Unlock()
+	ring, ok := s.eventRingsByTopic[topic]
+	if !ok {
+		ring = newEventRing(defaultRingSize)
+		s.eventRingsByTopic[topic] = ring
+	}
+	return ring
+}
+
+// Subscribe streams events matching req as an initial snapshot (if the topic
+// has a registered SnapshotFunc) followed by live events dispatched after the
+// snapshot's returned index. A non-zero req.Index skips the snapshot and
+// resumes after that index instead. The snapshot itself is staged here and only sent
+// once the caller is reading Events(), so a SnapshotFunc can append freely
+// without Subscribe blocking on an unbuffered channel no one has started
+// draining yet. Callers must call Subscription.Close when done.
+func (s *Service) Subscribe(req SubscribeRequest) (*Subscription, error) {
+	ring := s.ringForTopic(req.Topic)
+	sub := &Subscription{
+		events:  make(chan EventI),
+		closeCh: make(chan struct{}),
+	}
+
+	s.topicsMu.Lock()
+	snapshotFn := s.snapshotFuncsByTopic[req.Topic]
+	s.topicsMu.Unlock()
+
+	afterIdx := req.Index
+	var snapshot []EventI
+	if snapshotFn != nil && req.Index == 0 {
+		snapshotIdx, err := snapshotFn(req, func(ev EventI) {
+			snapshot = append(snapshot, ev)
+		})
+		if err != nil {
+			return nil, err
+		}
+		afterIdx = snapshotIdx
+	} else if req.Index == 0 {
+		// No snapshot and no explicit resume point: start live-from-now
+		// rather than replaying the ring's entire retained history.
+		afterIdx = ring.currentTail()
+	}
+
+	go s.followTopic(ring, req, afterIdx, snapshot, sub)
+
+	return sub, nil
+}
+
+func (s *Service) followTopic(ring *eventRing, req SubscribeRequest, afterIdx uint64, snapshot []EventI, sub *Subscription) {
+	defer close(sub.events)
+	for _, ev := range snapshot {
+		select {
+		case sub.events <- ev:
+		case <-sub.closeCh:
+			return
+		}
+	}
+	for {
+		ev, idx, caughtUp, ok := ring.since(afterIdx, sub.closeCh)
+		if !ok {
+			if caughtUp {
+				select {
+				case sub.events <- NewEvent(CAUGHT_UP_EVENT, &ErrCaughtUp{Topic: req.Topic}):
+				case <-sub.closeCh:
+				}
+			}
+			return
+		}
+		afterIdx = idx
+		if payload, ok := ev.Payload().(Payload); ok && req.Key != "" && !payload.MatchesKey(req.Key) {
+			continue
+		}
+		select {
+		case sub.events <- ev:
+		case <-sub.closeCh:
+			return
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// keyedPayload is a minimal Payload implementation for exercising
+// SubscribeRequest.Key filtering.
+type keyedPayload struct {
+	key string
+}
+
+func (p *keyedPayload) MatchesKey(key string) bool {
+	return p.key == key
+}
+
+// subscribedCatcher is a minimal stand-in for test_helpers.EventCatcher,
+// backed by Subscribe instead of AddEventListener, exercising Subscribe as a
+// way to consume a service's events. It only offers drainNext, not
+// EventCatcher's lookup methods.
+type subscribedCatcher struct {
+	sub *Subscription
+	evs []EventI
+}
+
+func newSubscribedCatcher(svc ServiceI, topic EventVariant) *subscribedCatcher {
+	sub, err := svc.Subscribe(SubscribeRequest{Topic: topic})
+	Expect(err).NotTo(HaveOccurred())
+	return &subscribedCatcher{sub: sub}
+}
+
+func (c *subscribedCatcher) drainNext() EventI {
+	ev := <-c.sub.Events()
+	c.evs = append(c.evs, ev)
+	return ev
+}
+
+var _ = Describe("Subscribe", func() {
+	var adderService *AdderService
+	BeforeEach(func() {
+		adderService = CreateServices()
+	})
+
+	It("delivers the first event ever dispatched on a topic", func() {
+		sub, err := adderService.Subscribe(SubscribeRequest{Topic: "someEvent"})
+		Expect(err).NotTo(HaveOccurred())
+		defer sub.Close()
+
+		Expect(adderService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		Eventually(sub.Events()).Should(Receive(Equal(EventI(NewEvent("someEvent", 1)))))
+	})
+
+	It("does not replay events dispatched before Subscribe", func() {
+		Expect(adderService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+
+		sub, err := adderService.Subscribe(SubscribeRequest{Topic: "someEvent"})
+		Expect(err).NotTo(HaveOccurred())
+		defer sub.Close()
+
+		Expect(adderService.Dispatch(NewEvent("someEvent", 2))).To(Succeed())
+
+		var ev EventI
+		Eventually(sub.Events()).Should(Receive(&ev))
+		Expect(ev.Payload()).To(Equal(2))
+	})
+
+	It("returns promptly even when a SnapshotFunc appends events", func() {
+		adderService.RegisterTopic("someEvent", func(req SubscribeRequest, append func(EventI)) (uint64, error) {
+			append(NewEvent("someEvent", 1))
+			append(NewEvent("someEvent", 2))
+			return 0, nil
+		})
+
+		done := make(chan *Subscription, 1)
+		go func() {
+			sub, err := adderService.Subscribe(SubscribeRequest{Topic: "someEvent"})
+			Expect(err).NotTo(HaveOccurred())
+			done <- sub
+		}()
+
+		var sub *Subscription
+		Eventually(done).Should(Receive(&sub))
+		defer sub.Close()
+
+		var first, second EventI
+		Eventually(sub.Events()).Should(Receive(&first))
+		Eventually(sub.Events()).Should(Receive(&second))
+		Expect(first.Payload()).To(Equal(1))
+		Expect(second.Payload()).To(Equal(2))
+	})
+
+	It("streams live events through a subscribedCatcher", func() {
+		catcher := newSubscribedCatcher(adderService, "someEvent")
+		defer catcher.sub.Close()
+
+		Expect(adderService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+		Expect(catcher.drainNext().Payload()).To(Equal(1))
+	})
+
+	When("a Key is given and the payload implements Payload", func() {
+		It("only delivers events whose payload matches the key", func() {
+			sub, err := adderService.Subscribe(SubscribeRequest{Topic: "someEvent", Key: "wanted"})
+			Expect(err).NotTo(HaveOccurred())
+			defer sub.Close()
+
+			Expect(adderService.Dispatch(NewEvent("someEvent", &keyedPayload{key: "unwanted"}))).To(Succeed())
+			Expect(adderService.Dispatch(NewEvent("someEvent", &keyedPayload{key: "wanted"}))).To(Succeed())
+
+			var ev EventI
+			Eventually(sub.Events()).Should(Receive(&ev))
+			Expect(ev.Payload()).To(Equal(&keyedPayload{key: "wanted"}))
+		})
+	})
+
+	It("resumes live events after a given Index instead of replaying from the start", func() {
+		Expect(adderService.Dispatch(NewEvent("someEvent", 1))).To(Succeed())
+		Expect(adderService.Dispatch(NewEvent("someEvent", 2))).To(Succeed())
+
+		resumeIdx := adderService.ringForTopic("someEvent").currentTail()
+
+		sub, err := adderService.Subscribe(SubscribeRequest{Topic: "someEvent", Index: resumeIdx})
+		Expect(err).NotTo(HaveOccurred())
+		defer sub.Close()
+
+		Expect(adderService.Dispatch(NewEvent("someEvent", 3))).To(Succeed())
+
+		var ev EventI
+		Eventually(sub.Events()).Should(Receive(&ev))
+		Expect(ev.Payload()).To(Equal(3))
+	})
+
+	It("reports a CAUGHT_UP_EVENT once a subscriber falls more than the ring size behind", func() {
+		sub, err := adderService.Subscribe(SubscribeRequest{Topic: "someEvent"})
+		Expect(err).NotTo(HaveOccurred())
+		defer sub.Close()
+
+		for i := 0; i < defaultRingSize*2; i++ {
+			Expect(adderService.Dispatch(NewEvent("someEvent", i))).To(Succeed())
+		}
+
+		// The subscriber's follower is still parked trying to deliver the
+		// very first event; draining it lets the follower notice how far
+		// the ring has since rolled past it.
+		var first EventI
+		Eventually(sub.Events()).Should(Receive(&first))
+		Expect(first.Payload()).To(Equal(0))
+
+		var caughtUp EventI
+		Eventually(sub.Events()).Should(Receive(&caughtUp))
+		Expect(caughtUp.Variant()).To(Equal(CAUGHT_UP_EVENT))
+		errCaughtUp, ok := caughtUp.Payload().(*ErrCaughtUp)
+		Expect(ok).To(BeTrue())
+		Expect(errCaughtUp.Topic).To(Equal(EventVariant("someEvent")))
+	})
+
+	It("closes the Events channel once Close is called", func() {
+		sub, err := adderService.Subscribe(SubscribeRequest{Topic: "someEvent"})
+		Expect(err).NotTo(HaveOccurred())
+		sub.Close()
+
+		Eventually(sub.Events()).Should(BeClosed())
+	})
+})